@@ -0,0 +1,147 @@
+package app
+
+import (
+	"image"
+	"math"
+)
+
+// blurhashComponentsX and blurhashComponentsY are the number of DCT components encodeBlurhash
+// uses along each axis, matching the 4x3 the request asks for.
+const (
+	blurhashComponentsX = 4
+	blurhashComponentsY = 3
+)
+
+// base83 is the alphabet blurhash strings are encoded in, per the blurhash spec.
+const base83 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// encodeBlurhash implements the blurhash encode algorithm (the same one
+// github.com/buckket/go-blurhash wraps) by hand, since this module has no go.mod to pin an
+// external dependency against. It DCT-encodes img into componentsX*componentsY components and
+// base83-encodes the result into a standard blurhash string any compliant decoder can render.
+func encodeBlurhash(img image.Image, componentsX, componentsY int) string {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return ""
+	}
+
+	factors := make([][3]float64, 0, componentsX*componentsY)
+	for j := 0; j < componentsY; j++ {
+		for i := 0; i < componentsX; i++ {
+			factors = append(factors, blurhashBasisFactor(img, bounds, i, j))
+		}
+	}
+
+	dc, ac := factors[0], factors[1:]
+
+	sizeFlag := (componentsX - 1) + (componentsY-1)*9
+	hash := encodeBase83(sizeFlag, 1)
+
+	quantisedMax := 0
+	maxValue := 1.0
+	if len(ac) > 0 {
+		actualMax := 0.0
+		for _, f := range ac {
+			for _, c := range f {
+				if a := math.Abs(c); a > actualMax {
+					actualMax = a
+				}
+			}
+		}
+		quantisedMax = int(clampFloat(math.Floor(actualMax*166-0.5), 0, 82))
+		maxValue = float64(quantisedMax+1) / 166
+	}
+	hash += encodeBase83(quantisedMax, 1)
+	hash += encodeBase83(encodeBlurhashDC(dc), 4)
+	for _, f := range ac {
+		hash += encodeBase83(encodeBlurhashAC(f, maxValue), 2)
+	}
+
+	return hash
+}
+
+// blurhashBasisFactor computes the (i, j) DCT basis coefficient of img's linear-RGB pixels.
+func blurhashBasisFactor(img image.Image, bounds image.Rectangle, i, j int) [3]float64 {
+	width, height := bounds.Dx(), bounds.Dy()
+
+	normalisation := 2.0
+	if i == 0 && j == 0 {
+		normalisation = 1.0
+	}
+
+	var r, g, b float64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			basis := normalisation *
+				math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+				math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+
+			cr, cg, cb, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			r += basis * srgbToLinear(float64(cr>>8))
+			g += basis * srgbToLinear(float64(cg>>8))
+			b += basis * srgbToLinear(float64(cb>>8))
+		}
+	}
+
+	scale := 1.0 / float64(width*height)
+	return [3]float64{r * scale, g * scale, b * scale}
+}
+
+func encodeBlurhashDC(c [3]float64) int {
+	r := linearToSrgb(c[0])
+	g := linearToSrgb(c[1])
+	b := linearToSrgb(c[2])
+	return r<<16 + g<<8 + b
+}
+
+func encodeBlurhashAC(c [3]float64, maxValue float64) int {
+	quantise := func(v float64) int {
+		q := math.Floor(signedPow(v/maxValue, 0.5)*9 + 9.5)
+		return int(clampFloat(q, 0, 18))
+	}
+	return quantise(c[0])*19*19 + quantise(c[1])*19 + quantise(c[2])
+}
+
+func encodeBase83(value, length int) string {
+	digits := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		digits[i] = base83[value%83]
+		value /= 83
+	}
+	return string(digits)
+}
+
+func srgbToLinear(v float64) float64 {
+	v /= 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSrgb(v float64) int {
+	v = clampFloat(v, 0, 1)
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signedPow(v, exp float64) float64 {
+	sign := 1.0
+	if v < 0 {
+		sign = -1.0
+	}
+	return sign * math.Pow(math.Abs(v), exp)
+}
+
+func clampFloat(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
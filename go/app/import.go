@@ -0,0 +1,342 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"mercari-build-training/app/response"
+	"mercari-build-training/app/stream"
+)
+
+// defaultImportWorkers is how many rows ImportItems processes concurrently by default. It
+// can be overridden with the MERCARI_IMPORT_WORKERS environment variable.
+const defaultImportWorkers = 5
+
+func importWorkerCount() int {
+	if v := os.Getenv("MERCARI_IMPORT_WORKERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultImportWorkers
+}
+
+// importRow is one entry of a POST /items/import manifest, whether it came from the JSON
+// body form or a CSV/JSONL manifest file. Exactly one of ImageURL and ImagePart should be
+// set: ImageURL is fetched over HTTP, ImagePart names a file part uploaded alongside the
+// manifest in the same multipart/form-data request.
+type importRow struct {
+	Name      string `json:"name"`
+	Category  string `json:"category"`
+	ImageURL  string `json:"image_url"`
+	ImagePart string `json:"image_part"`
+}
+
+// importRowResult is one ndjson line ImportItems writes back per row.
+type importRowResult struct {
+	Row    int    `json:"row"`
+	Status string `json:"status,omitempty"`
+	ID     int    `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportItems handles POST /items/import: a bounded pool of workers resolves each row's
+// image (downloading image_url, or reading the image_part upload alongside a multipart
+// manifest) and inserts it, streaming one ndjson result line per row as it completes. A plain
+// JSON body (a `[{name,category,image_url}, ...]` array) inserts each row independently,
+// reporting its real assigned id as soon as the row is inserted; a multipart manifest (CSV or
+// JSONL) inserts all rows in a single transaction, so one bad row rolls the whole import back
+// instead of leaving the catalog half-written.
+func (s *Handlers) ImportItems(w http.ResponseWriter, r *http.Request) {
+	sw, ok := stream.New(w)
+	if !ok {
+		response.Error(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+
+	rows, atomic, form, err := parseImportRequest(r)
+	if err != nil {
+		sw.WriteError(err)
+		return
+	}
+
+	items := make([]*Item, len(rows))
+	failed := false
+	s.runImportWorkers(r.Context(), rows, form, func(idx int, item *Item, err error) {
+		if err != nil {
+			failed = true
+			sw.WriteJSON(importRowResult{Row: idx, Error: err.Error()})
+			return
+		}
+
+		if !atomic {
+			if err := s.itemRepo.Insert(r.Context(), item); err != nil {
+				failed = true
+				sw.WriteJSON(importRowResult{Row: idx, Error: err.Error()})
+				return
+			}
+			sw.WriteJSON(importRowResult{Row: idx, Status: "ok", ID: item.ID})
+		}
+		items[idx] = item
+	})
+
+	if !atomic {
+		return
+	}
+
+	if failed {
+		sw.WriteError(fmt.Errorf("import transaction rolled back: %d of %d rows failed", len(rows)-countOK(items), len(rows)))
+		return
+	}
+
+	if err := s.itemRepo.InsertBatch(r.Context(), items); err != nil {
+		sw.WriteError(fmt.Errorf("import transaction rolled back: %w", err))
+		return
+	}
+
+	for idx, item := range items {
+		sw.WriteJSON(importRowResult{Row: idx, Status: "ok", ID: item.ID})
+	}
+}
+
+// countOK returns how many entries of items are non-nil, i.e. how many rows succeeded.
+func countOK(items []*Item) int {
+	n := 0
+	for _, item := range items {
+		if item != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// runImportWorkers fetches and stores each row's image across a bounded worker pool,
+// reporting one result per row through report. report is only ever called from the calling
+// goroutine, one row at a time, so callers that write to a shared, non-concurrency-safe
+// sink (like an http.ResponseWriter) from it don't need to synchronize it themselves.
+// runImportWorkers does not insert anything itself: the caller decides whether each row is
+// inserted independently or batched into one transaction.
+func (s *Handlers) runImportWorkers(ctx context.Context, rows []importRow, form *multipart.Form, report func(idx int, item *Item, err error)) {
+	type job struct {
+		idx int
+		row importRow
+	}
+
+	type result struct {
+		idx  int
+		item *Item
+		err  error
+	}
+
+	jobs := make(chan job)
+	results := make(chan result)
+	var wg sync.WaitGroup
+
+	for w := 0; w < importWorkerCount(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				item, err := s.fetchAndStoreRow(ctx, j.row, form)
+				results <- result{idx: j.idx, item: item, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for idx, row := range rows {
+			jobs <- job{idx: idx, row: row}
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	for r := range results {
+		report(r.idx, r.item, r.err)
+	}
+}
+
+// fetchAndStoreRow resolves a row's image -- downloading row.ImageURL, or, in multipart
+// manifest mode, opening the upload part named row.ImagePart out of form -- streams it to a
+// temp file and stores it via storeImage, then builds the Item to insert. It doesn't touch
+// the database itself.
+func (s *Handlers) fetchAndStoreRow(ctx context.Context, row importRow, form *multipart.Form) (*Item, error) {
+	if row.Name == "" || row.Category == "" {
+		return nil, fmt.Errorf("name and category are required")
+	}
+
+	var src io.Reader
+	switch {
+	case row.ImageURL != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, row.ImageURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request for %s: %w", row.ImageURL, err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", row.ImageURL, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch %s: status %d", row.ImageURL, resp.StatusCode)
+		}
+		src = resp.Body
+
+	case row.ImagePart != "":
+		if form == nil {
+			return nil, fmt.Errorf("image_part %q requires a multipart manifest upload", row.ImagePart)
+		}
+		headers := form.File[row.ImagePart]
+		if len(headers) == 0 {
+			return nil, fmt.Errorf("no uploaded part named %q", row.ImagePart)
+		}
+		file, err := headers[0].Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open part %q: %w", row.ImagePart, err)
+		}
+		defer file.Close()
+		src = file
+
+	default:
+		return nil, fmt.Errorf("image_url or image_part is required")
+	}
+
+	tempPath, hash, err := s.streamImageToTemp(src, maxImageSizeBytes(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image for %s: %w", row.Name, err)
+	}
+
+	stored, err := s.storeImage(tempPath, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store image for %s: %w", row.Name, err)
+	}
+
+	return &Item{
+		Name:      row.Name,
+		Category:  row.Category,
+		ImageName: stored.fileName,
+		ImageHash: stored.hash,
+		Mime:      stored.mime,
+		Width:     stored.width,
+		Height:    stored.height,
+		Blurhash:  stored.blurhash,
+	}, nil
+}
+
+// parseImportRequest reads either a JSON body (`[{...}, ...]`) or a multipart manifest
+// (a "manifest" field holding CSV or JSONL, plus zero or more image parts that rows can
+// reference by name via image_part) and returns its rows. atomic reports whether the rows
+// came from a manifest upload, in which case the caller inserts them as one transaction. form
+// is the parsed multipart form the rows' image_part fields resolve against; it's nil for a
+// plain JSON body.
+func parseImportRequest(r *http.Request) (rows []importRow, atomic bool, form *multipart.Form, err error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return nil, false, nil, fmt.Errorf("failed to parse multipart form: %w", err)
+		}
+
+		manifest, _, err := r.FormFile("manifest")
+		if err != nil {
+			return nil, false, nil, fmt.Errorf("failed to get manifest file: %w", err)
+		}
+		defer manifest.Close()
+
+		rows, err := parseManifest(manifest)
+		return rows, true, r.MultipartForm, err
+	}
+
+	var bodyRows []importRow
+	if err := json.NewDecoder(r.Body).Decode(&bodyRows); err != nil {
+		return nil, false, nil, fmt.Errorf("failed to parse import body: %w", err)
+	}
+	return bodyRows, false, nil, nil
+}
+
+// parseManifest reads a CSV or JSONL manifest. JSONL is detected by the first non-whitespace
+// byte being '{'; otherwise it's parsed as CSV with a name,category,image_url header.
+func parseManifest(r io.Reader) ([]importRow, error) {
+	buffered := bufio.NewReader(r)
+	var first byte
+	for {
+		b, err := buffered.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b)) {
+			buffered.UnreadByte()
+			first = b
+			break
+		}
+	}
+
+	if first == '{' {
+		var rows []importRow
+		scanner := bufio.NewScanner(buffered)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var row importRow
+			if err := json.Unmarshal([]byte(line), &row); err != nil {
+				return nil, fmt.Errorf("failed to parse manifest line: %w", err)
+			}
+			rows = append(rows, row)
+		}
+		return rows, scanner.Err()
+	}
+
+	csvReader := csv.NewReader(buffered)
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for idx, name := range header {
+		columns[strings.TrimSpace(name)] = idx
+	}
+
+	var rows []importRow
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest row: %w", err)
+		}
+
+		row := importRow{}
+		if idx, ok := columns["name"]; ok && idx < len(record) {
+			row.Name = record[idx]
+		}
+		if idx, ok := columns["category"]; ok && idx < len(record) {
+			row.Category = record[idx]
+		}
+		if idx, ok := columns["image_url"]; ok && idx < len(record) {
+			row.ImageURL = record[idx]
+		}
+		if idx, ok := columns["image_part"]; ok && idx < len(record) {
+			row.ImagePart = record[idx]
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -8,6 +8,8 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -16,10 +18,18 @@ var errImageNotFound = errors.New("image not found")
 var errItemNotFound = errors.New("item not found")
 
 type Item struct {
-	ID   int    `db:"id" json:"-"`
-	Name string `db:"name" json:"name"`
-	Category string `db:"-" json:"category"`
+	ID        int    `db:"id" json:"-"`
+	Name      string `db:"name" json:"name"`
+	Category  string `db:"-" json:"category"`
 	ImageName string `db:"image_name" json:"image_name"`
+	// ImageHash is the sha256 hex digest of the stored image, also used as its file name stem.
+	ImageHash string `db:"image_hash" json:"image_hash"`
+	// Mime is the sniffed content type of the stored image (image/jpeg, image/png or image/webp).
+	Mime   string `db:"mime" json:"mime"`
+	Width  int    `db:"width" json:"width"`
+	Height int    `db:"height" json:"height"`
+	// Blurhash is a compact placeholder the frontend can render while the full image loads.
+	Blurhash string `db:"blurhash" json:"blurhash"`
 }
 
 // Please run `go generate ./...` to generate the mock implementation
@@ -28,9 +38,27 @@ type Item struct {
 //go:generate go run go.uber.org/mock/mockgen -source=$GOFILE -package=${GOPACKAGE} -destination=./mock_$GOFILE
 type ItemRepository interface {
 	Insert(ctx context.Context, item *Item) error
+	InsertBatch(ctx context.Context, items []*Item) error
 	GetAll(ctx context.Context) ([]Item, error)
 	GetByID(ctx context.Context, item_id string) (Item, error)
+	GetByRef(ctx context.Context, ref ItemRef) (Item, error)
 	Search(ctx context.Context, keyword string) ([]Item, error)
+	List(ctx context.Context, query ListItemsQuery) (items []Item, total int, err error)
+}
+
+// ListItemsQuery narrows and paginates a List call. The zero value matches every item, in
+// the same order GetAll would have returned them, so callers that don't set any field keep
+// the old GetAll behavior.
+type ListItemsQuery struct {
+	Category string
+	Name     string
+	Hash     string
+	SinceID  int
+	// Limit is the max number of items to return; 0 means unlimited.
+	Limit  int
+	Offset int
+	// Order is "asc" or "desc" by items.id; defaults to "asc".
+	Order string
 }
 
 // itemRepository is an implementation of ItemRepository
@@ -55,9 +83,40 @@ func NewItemRepository(db *sql.DB) (ItemRepository, error) {
 		slog.Error("failed to execute schema file: ", "error", err)
 		return nil, err
 	}
+
+	if err := migrateImageMetadataColumns(db); err != nil {
+		slog.Error("failed to migrate image metadata columns: ", "error", err)
+		return nil, err
+	}
+
 	return &itemRepository{DB: db}, nil
 }
 
+// migrateImageMetadataColumns adds the image ingestion metadata columns to a pre-existing
+// items table. It's run on every startup and ignores "duplicate column" errors so it stays
+// idempotent without needing a separate migrations table.
+func migrateImageMetadataColumns(db *sql.DB) error {
+	statements := []string{
+		"ALTER TABLE items ADD COLUMN image_hash TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE items ADD COLUMN mime TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE items ADD COLUMN width INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE items ADD COLUMN height INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE items ADD COLUMN blurhash TEXT NOT NULL DEFAULT ''",
+		"CREATE INDEX IF NOT EXISTS idx_items_name_image_hash ON items (name, image_hash)",
+	}
+
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			if strings.Contains(err.Error(), "duplicate column name") {
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Insert inserts an item into the repository.
 func (i *itemRepository) Insert(ctx context.Context, item *Item) error {
 	tx, err := i.DB.BeginTx(ctx, nil)
@@ -65,15 +124,43 @@ func (i *itemRepository) Insert(ctx context.Context, item *Item) error {
 		return err
 	}
 
+	if err := insertItemTx(ctx, tx, item); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InsertBatch inserts every item in one transaction, rolling back all of them if any insert
+// fails, so a bad row in a bulk import can't leave the catalog half-written.
+func (i *itemRepository) InsertBatch(ctx context.Context, items []*Item) error {
+	tx, err := i.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := insertItemTx(ctx, tx, item); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("insert %q: %w", item.Name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertItemTx runs Insert's dedup-check/category-lookup/insert logic against an
+// already-open transaction, so Insert and InsertBatch can share it.
+func insertItemTx(ctx context.Context, tx *sql.Tx, item *Item) error {
 	// Check if the item already exists
 	var existingItem Item
-	err = tx.QueryRowContext(ctx, "SELECT id FROM items WHERE name = ? AND category_id = (SELECT id FROM categories WHERE name = ?)", item.Name, item.Category).Scan(&existingItem.ID)
+	err := tx.QueryRowContext(ctx, "SELECT id FROM items WHERE name = ? AND category_id = (SELECT id FROM categories WHERE name = ?)", item.Name, item.Category).Scan(&existingItem.ID)
 	if err == nil {
 		slog.Info("item already exists", "name", item.Name, "category", item.Category)
-		tx.Rollback()
+		item.ID = existingItem.ID
 		return nil
 	} else if err != sql.ErrNoRows {
-		tx.Rollback()
 		return err
 	}
 
@@ -83,30 +170,32 @@ func (i *itemRepository) Insert(ctx context.Context, item *Item) error {
 		if err == sql.ErrNoRows {
 			result, err := tx.ExecContext(ctx, "INSERT INTO categories (name) VALUES (?)", item.Category)
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
 			lastID, err := result.LastInsertId()
 			if err != nil {
-				tx.Rollback()
 				return err
 			}
 			categoryID = int(lastID)
 		} else {
-			tx.Rollback()
 			return err
 		}
 	}
 
-	_, err = tx.ExecContext(ctx,
-		"INSERT INTO items (name, category_id, image_name) VALUES (?, ?, ?)",
-		item.Name, categoryID, item.ImageName)
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO items (name, category_id, image_name, image_hash, mime, width, height, blurhash) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		item.Name, categoryID, item.ImageName, item.ImageHash, item.Mime, item.Width, item.Height, item.Blurhash)
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
 
-	return tx.Commit()
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	item.ID = int(lastID)
+
+	return nil
 }
 
 // StoreImage stores an image and returns an error if any.
@@ -128,14 +217,25 @@ func StoreImage(fileName string, image []byte) error {
 	return nil
 }
 
+// itemColumns is the column list shared by every SELECT against items so the Scan order
+// in GetAll/GetByID/Search/List/GetByRef always lines up with the Item fields it fills in.
+const itemColumns = `items.id, items.name, categories.name AS category_name, items.image_name,
+		items.image_hash, items.mime, items.width, items.height, items.blurhash`
+
+func scanItem(row interface{ Scan(dest ...any) error }) (Item, error) {
+	var item Item
+	err := row.Scan(&item.ID, &item.Name, &item.Category, &item.ImageName,
+		&item.ImageHash, &item.Mime, &item.Width, &item.Height, &item.Blurhash)
+	return item, err
+}
 
 func (i *itemRepository) GetAll(ctx context.Context) ([]Item, error) {
 	query := `
-		SELECT items.id, items.name, categories.name AS category_name, items.image_name
+		SELECT ` + itemColumns + `
 		FROM items
 		JOIN categories ON items.category_id = categories.id
 		`
-	rows, err := i.DB.Query(query)
+	rows, err := i.DB.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -143,8 +243,7 @@ func (i *itemRepository) GetAll(ctx context.Context) ([]Item, error) {
 
 	var items []Item
 	for rows.Next() {
-		var item Item
-		err := rows.Scan(&item.ID, &item.Name, &item.Category, &item.ImageName)
+		item, err := scanItem(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -154,16 +253,94 @@ func (i *itemRepository) GetAll(ctx context.Context) ([]Item, error) {
 	return items, nil
 }
 
+// List returns items matching query alongside the total count of matching rows (ignoring
+// Limit/Offset), so callers can paginate without a second round trip.
+func (i *itemRepository) List(ctx context.Context, query ListItemsQuery) ([]Item, int, error) {
+	where := []string{"1=1"}
+	args := []any{}
+
+	if query.Category != "" {
+		where = append(where, "categories.name = ?")
+		args = append(args, query.Category)
+	}
+	if query.Name != "" {
+		where = append(where, "items.name LIKE ?")
+		args = append(args, "%"+query.Name+"%")
+	}
+	if query.Hash != "" {
+		where = append(where, "items.image_hash = ?")
+		args = append(args, query.Hash)
+	}
+	if query.SinceID != 0 {
+		where = append(where, "items.id > ?")
+		args = append(args, query.SinceID)
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	order := "ASC"
+	if strings.EqualFold(query.Order, "desc") {
+		order = "DESC"
+	}
+
+	var total int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM items
+		JOIN categories ON items.category_id = categories.id
+		WHERE ` + whereClause
+	if err := i.DB.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	listQuery := `
+		SELECT ` + itemColumns + `
+		FROM items
+		JOIN categories ON items.category_id = categories.id
+		WHERE ` + whereClause + `
+		ORDER BY items.id ` + order
+
+	listArgs := append([]any{}, args...)
+	if query.Limit > 0 {
+		listQuery += " LIMIT ?"
+		listArgs = append(listArgs, query.Limit)
+		if query.Offset > 0 {
+			listQuery += " OFFSET ?"
+			listArgs = append(listArgs, query.Offset)
+		}
+	} else if query.Offset > 0 {
+		// sqlite requires a LIMIT clause before OFFSET; -1 means "no limit", so an offset
+		// without a limit still paginates instead of silently being ignored.
+		listQuery += " LIMIT -1 OFFSET ?"
+		listArgs = append(listArgs, query.Offset)
+	}
+
+	rows, err := i.DB.QueryContext(ctx, listQuery, listArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var items []Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+
+	return items, total, nil
+}
+
 func (i *itemRepository) GetByID(ctx context.Context, item_id string) (Item, error) {
 	query := `
-		SELECT items.id, items.name, categories.name AS category_name, items.image_name
+		SELECT ` + itemColumns + `
 		FROM items
 		JOIN categories ON items.category_id = categories.id
 		WHERE items.id = ?
 		`
-	row := i.DB.QueryRow(query, item_id)
-	var item Item
-	err := row.Scan(&item.ID, &item.Name, &item.Category, &item.ImageName)
+	row := i.DB.QueryRowContext(ctx, query, item_id)
+	item, err := scanItem(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return Item{}, errItemNotFound
@@ -174,14 +351,58 @@ func (i *itemRepository) GetByID(ctx context.Context, item_id string) (Item, err
 	return item, nil
 }
 
+// GetByRef resolves an ItemRef to its Item, dispatching to a different query per reference
+// kind: a numeric id goes straight to GetByID, while category/name[@sha256:...] references
+// look the item up by its category and name, optionally pinned to an exact image digest.
+func (i *itemRepository) GetByRef(ctx context.Context, ref ItemRef) (Item, error) {
+	switch ref.Kind {
+	case ItemRefID:
+		return i.GetByID(ctx, strconv.Itoa(ref.ID))
+
+	case ItemRefName:
+		query := `
+			SELECT ` + itemColumns + `
+			FROM items
+			JOIN categories ON items.category_id = categories.id
+			WHERE categories.name = ? AND items.name = ?
+			`
+		row := i.DB.QueryRowContext(ctx, query, ref.Category, ref.Name)
+		return scanItemOrNotFound(row)
+
+	case ItemRefDigest:
+		query := `
+			SELECT ` + itemColumns + `
+			FROM items
+			JOIN categories ON items.category_id = categories.id
+			WHERE categories.name = ? AND items.name = ? AND items.image_hash = ?
+			`
+		row := i.DB.QueryRowContext(ctx, query, ref.Category, ref.Name, ref.Digest)
+		return scanItemOrNotFound(row)
+
+	default:
+		return Item{}, fmt.Errorf("unsupported item reference kind: %v", ref.Kind)
+	}
+}
+
+func scanItemOrNotFound(row *sql.Row) (Item, error) {
+	item, err := scanItem(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Item{}, errItemNotFound
+		}
+		return Item{}, err
+	}
+	return item, nil
+}
+
 func (i *itemRepository) Search(ctx context.Context, keyword string) ([]Item, error) {
 	query := `
-        SELECT items.name, categories.name AS category_name, items.image_name
+        SELECT ` + itemColumns + `
 		FROM items
 		JOIN categories ON items.category_id = categories.id
 		WHERE items.name LIKE ? OR categories.name LIKE ?
         `
-	rows, err := i.DB.Query(query, "%"+keyword+"%")
+	rows, err := i.DB.QueryContext(ctx, query, "%"+keyword+"%", "%"+keyword+"%")
 	if err != nil {
 		return nil, err
 	}
@@ -189,8 +410,7 @@ func (i *itemRepository) Search(ctx context.Context, keyword string) ([]Item, er
 
 	var items []Item
 	for rows.Next() {
-		var item Item
-		err := rows.Scan(&item.ID, &item.Name, &item.Category, &item.ImageName)
+		item, err := scanItem(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -198,4 +418,4 @@ func (i *itemRepository) Search(ctx context.Context, keyword string) ([]Item, er
 	}
 
 	return items, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newTestItemRepository builds an itemRepository against a fresh in-memory sqlite3 database.
+// It creates the base schema by hand rather than reading db/items.sql (NewItemRepository's
+// usual source), since that file isn't part of this module, then runs the same
+// migrateImageMetadataColumns every real repository goes through.
+func newTestItemRepository(t *testing.T) *itemRepository {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	schema := `
+		CREATE TABLE categories (
+			id   INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE CHECK (name <> '')
+		);
+		CREATE TABLE items (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			name        TEXT NOT NULL,
+			category_id INTEGER NOT NULL,
+			image_name  TEXT NOT NULL DEFAULT ''
+		);
+		`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	if err := migrateImageMetadataColumns(db); err != nil {
+		t.Fatalf("failed to migrate image metadata columns: %v", err)
+	}
+
+	return &itemRepository{DB: db}
+}
+
+func TestItemRepositoryInsertBatch(t *testing.T) {
+	repo := newTestItemRepository(t)
+	ctx := context.Background()
+
+	items := []*Item{
+		{Name: "iphone-13", Category: "phones", ImageHash: "aaa"},
+		{Name: "galaxy-s21", Category: "phones", ImageHash: "bbb"},
+	}
+	if err := repo.InsertBatch(ctx, items); err != nil {
+		t.Fatalf("InsertBatch returned unexpected error: %v", err)
+	}
+	for _, item := range items {
+		if item.ID == 0 {
+			t.Fatalf("InsertBatch left %q without an assigned id", item.Name)
+		}
+	}
+
+	all, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll returned unexpected error: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("GetAll returned %d items, want 2", len(all))
+	}
+}
+
+func TestItemRepositoryInsertBatchRollsBackOnFailure(t *testing.T) {
+	repo := newTestItemRepository(t)
+	ctx := context.Background()
+
+	items := []*Item{
+		{Name: "iphone-13", Category: "phones", ImageHash: "aaa"},
+		{Name: "bad-item", Category: "", ImageHash: "bbb"}, // empty category violates the categories CHECK constraint
+	}
+	if err := repo.InsertBatch(ctx, items); err == nil {
+		t.Fatal("InsertBatch with a bad row returned no error, want one")
+	}
+
+	all, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll returned unexpected error: %v", err)
+	}
+	if len(all) != 0 {
+		t.Fatalf("GetAll returned %d items after a rolled-back batch, want 0", len(all))
+	}
+}
+
+func TestItemRepositoryList(t *testing.T) {
+	repo := newTestItemRepository(t)
+	ctx := context.Background()
+
+	items := []*Item{
+		{Name: "iphone-13", Category: "phones", ImageHash: "aaa"},
+		{Name: "iphone-14", Category: "phones", ImageHash: "bbb"},
+		{Name: "galaxy-s21", Category: "phones", ImageHash: "ccc"},
+		{Name: "macbook-air", Category: "laptops", ImageHash: "ddd"},
+	}
+	if err := repo.InsertBatch(ctx, items); err != nil {
+		t.Fatalf("InsertBatch returned unexpected error: %v", err)
+	}
+
+	t.Run("filters by category", func(t *testing.T) {
+		got, total, err := repo.List(ctx, ListItemsQuery{Category: "laptops"})
+		if err != nil {
+			t.Fatalf("List returned unexpected error: %v", err)
+		}
+		if total != 1 || len(got) != 1 || got[0].Name != "macbook-air" {
+			t.Fatalf("List(category=laptops) = %+v, total %d; want 1 macbook-air", got, total)
+		}
+	})
+
+	t.Run("filters by name substring", func(t *testing.T) {
+		got, total, err := repo.List(ctx, ListItemsQuery{Name: "iphone"})
+		if err != nil {
+			t.Fatalf("List returned unexpected error: %v", err)
+		}
+		if total != 2 || len(got) != 2 {
+			t.Fatalf("List(name=iphone) returned %d items, total %d; want 2", len(got), total)
+		}
+	})
+
+	t.Run("filters by image hash", func(t *testing.T) {
+		got, total, err := repo.List(ctx, ListItemsQuery{Hash: "ccc"})
+		if err != nil {
+			t.Fatalf("List returned unexpected error: %v", err)
+		}
+		if total != 1 || len(got) != 1 || got[0].Name != "galaxy-s21" {
+			t.Fatalf("List(hash=ccc) = %+v, total %d; want 1 galaxy-s21", got, total)
+		}
+	})
+
+	t.Run("paginates with limit and offset", func(t *testing.T) {
+		got, total, err := repo.List(ctx, ListItemsQuery{Category: "phones", Limit: 1, Offset: 1})
+		if err != nil {
+			t.Fatalf("List returned unexpected error: %v", err)
+		}
+		if total != 3 {
+			t.Fatalf("List total = %d, want 3 (limit/offset shouldn't affect total)", total)
+		}
+		if len(got) != 1 || got[0].Name != "iphone-14" {
+			t.Fatalf("List(limit=1, offset=1) = %+v, want 1 iphone-14", got)
+		}
+	})
+
+	t.Run("offset without limit still paginates", func(t *testing.T) {
+		got, total, err := repo.List(ctx, ListItemsQuery{Category: "phones", Offset: 1})
+		if err != nil {
+			t.Fatalf("List returned unexpected error: %v", err)
+		}
+		if total != 3 {
+			t.Fatalf("List total = %d, want 3", total)
+		}
+		if len(got) != 2 {
+			t.Fatalf("List(offset=1) returned %d items, want 2 (total minus the offset)", len(got))
+		}
+	})
+
+	t.Run("since id", func(t *testing.T) {
+		got, _, err := repo.List(ctx, ListItemsQuery{SinceID: items[1].ID})
+		if err != nil {
+			t.Fatalf("List returned unexpected error: %v", err)
+		}
+		for _, item := range got {
+			if item.ID <= items[1].ID {
+				t.Fatalf("List(sinceID=%d) returned item with id %d", items[1].ID, item.ID)
+			}
+		}
+	})
+}
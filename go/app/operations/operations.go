@@ -0,0 +1,133 @@
+// Package operations tracks long-running work (like image ingestion) that a handler kicks
+// off in a goroutine and returns a handle for, so the caller can poll for its outcome instead
+// of blocking the request on it.
+package operations
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status values an Operation moves through. It never goes backwards.
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusError   = "error"
+)
+
+// Operation is a snapshot of a single tracked background job.
+type Operation struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Result    any       `json:"result,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Registry is an in-memory store of Operations. The zero value is not usable; use New.
+type Registry struct {
+	mu  sync.Mutex
+	ops map[string]*Operation
+}
+
+// New creates an empty Registry.
+func New() *Registry {
+	return &Registry{ops: make(map[string]*Operation)}
+}
+
+// Start creates a new pending Operation and returns it. The caller is expected to move it to
+// running/done/error as the underlying work progresses.
+func (r *Registry) Start() *Operation {
+	now := time.Now()
+	op := &Operation{
+		ID:        newID(),
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	r.mu.Lock()
+	r.ops[op.ID] = op
+	r.mu.Unlock()
+
+	return op
+}
+
+// Get returns the Operation for id, if any.
+func (r *Registry) Get(id string) (Operation, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	op, ok := r.ops[id]
+	if !ok {
+		return Operation{}, false
+	}
+	return *op, true
+}
+
+// List returns every tracked Operation in no particular order.
+func (r *Registry) List() []Operation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ops := make([]Operation, 0, len(r.ops))
+	for _, op := range r.ops {
+		ops = append(ops, *op)
+	}
+	return ops
+}
+
+// SetRunning marks id as running.
+func (r *Registry) SetRunning(id string) {
+	r.update(id, func(op *Operation) {
+		op.Status = StatusRunning
+	})
+}
+
+// SetDone marks id as done with the given result payload.
+func (r *Registry) SetDone(id string, result any) {
+	r.update(id, func(op *Operation) {
+		op.Status = StatusDone
+		op.Result = result
+	})
+}
+
+// SetError marks id as failed with err's message.
+func (r *Registry) SetError(id string, err error) {
+	r.update(id, func(op *Operation) {
+		op.Status = StatusError
+		op.Error = err.Error()
+	})
+}
+
+func (r *Registry) update(id string, mutate func(*Operation)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	op, ok := r.ops[id]
+	if !ok {
+		return
+	}
+	mutate(op)
+	op.UpdatedAt = time.Now()
+}
+
+// newID generates a random operation id as a version 4 (random) UUID, per RFC 4122, without
+// pulling in an external dependency for it.
+func newID() string {
+	var b [16]byte
+	// crypto/rand.Read on a fixed-size array never fails in practice; if it does, newID
+	// panics rather than handing out a non-random (all-zero) id.
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("operations: failed to generate id: %v", err))
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ItemRefKind distinguishes the three forms ParseItemReference accepts.
+type ItemRefKind int
+
+const (
+	// ItemRefID is a bare numeric database id, e.g. "42".
+	ItemRefID ItemRefKind = iota
+	// ItemRefName is a "category/name" pair.
+	ItemRefName
+	// ItemRefDigest is a "category/name@sha256:<hex>" pair pinned to a specific image.
+	ItemRefDigest
+)
+
+// ItemRef is a parsed item reference, as accepted by GET /items/{ref}.
+type ItemRef struct {
+	Kind     ItemRefKind
+	ID       int
+	Category string
+	Name     string
+	// Digest is the lowercase hex sha256 digest from an ItemRefDigest reference.
+	Digest string
+}
+
+// ParseItemReference parses the {ref} path segment of GET /items/{ref} into one of:
+//   - a numeric id ("42")
+//   - a category/name pair ("phones/iphone-13")
+//   - a category/name pinned to an image digest ("phones/iphone-13@sha256:<64 hex chars>")
+func ParseItemReference(ref string) (ItemRef, error) {
+	if ref == "" {
+		return ItemRef{}, errors.New("item reference is required")
+	}
+
+	if id, err := strconv.Atoi(ref); err == nil {
+		return ItemRef{Kind: ItemRefID, ID: id}, nil
+	}
+
+	path, digest, pinned := strings.Cut(ref, "@")
+
+	category, name, ok := strings.Cut(path, "/")
+	if !ok || category == "" || name == "" {
+		return ItemRef{}, fmt.Errorf("invalid item reference: %s", ref)
+	}
+
+	if !pinned {
+		return ItemRef{Kind: ItemRefName, Category: category, Name: name}, nil
+	}
+
+	hex, ok := strings.CutPrefix(digest, "sha256:")
+	if !ok || len(hex) != 64 || !isHexString(hex) {
+		return ItemRef{}, fmt.Errorf("invalid digest in item reference: %s", ref)
+	}
+
+	return ItemRef{Kind: ItemRefDigest, Category: category, Name: name, Digest: strings.ToLower(hex)}, nil
+}
+
+func isHexString(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
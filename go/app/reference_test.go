@@ -0,0 +1,96 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseItemReference(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		want    ItemRef
+		wantErr bool
+	}{
+		{
+			name: "numeric id",
+			ref:  "42",
+			want: ItemRef{Kind: ItemRefID, ID: 42},
+		},
+		{
+			name: "category/name",
+			ref:  "phones/iphone-13",
+			want: ItemRef{Kind: ItemRefName, Category: "phones", Name: "iphone-13"},
+		},
+		{
+			name: "category/name pinned to a digest",
+			ref:  "phones/iphone-13@sha256:" + sha256ZeroHex,
+			want: ItemRef{Kind: ItemRefDigest, Category: "phones", Name: "iphone-13", Digest: sha256ZeroHex},
+		},
+		{
+			name: "digest is lowercased",
+			ref:  "phones/iphone-13@sha256:" + upperHex(sha256ZeroHex),
+			want: ItemRef{Kind: ItemRefDigest, Category: "phones", Name: "iphone-13", Digest: sha256ZeroHex},
+		},
+		{
+			name:    "empty reference",
+			ref:     "",
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			ref:     "phones/",
+			wantErr: true,
+		},
+		{
+			name:    "missing category",
+			ref:     "/iphone-13",
+			wantErr: true,
+		},
+		{
+			name:    "no slash and not numeric",
+			ref:     "iphone-13",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported digest algorithm",
+			ref:     "phones/iphone-13@md5:" + sha256ZeroHex,
+			wantErr: true,
+		},
+		{
+			name:    "digest too short",
+			ref:     "phones/iphone-13@sha256:abcd",
+			wantErr: true,
+		},
+		{
+			name:    "digest not hex",
+			ref:     "phones/iphone-13@sha256:" + sha256NonHex,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseItemReference(tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseItemReference(%q) = %+v, want error", tt.ref, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseItemReference(%q) returned unexpected error: %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseItemReference(%q) = %+v, want %+v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+var sha256ZeroHex = strings.Repeat("0", 64)
+var sha256NonHex = "zz" + strings.Repeat("0", 62)
+
+func upperHex(s string) string {
+	return strings.ToUpper(s)
+}
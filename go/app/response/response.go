@@ -0,0 +1,48 @@
+// Package response centralizes how handlers write HTTP responses, so every endpoint sets
+// the same Content-Type and wraps errors in the same envelope instead of each handler
+// re-implementing json.NewEncoder/http.Error by hand.
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// errorEnvelope is the JSON shape of every error response written by this package.
+type errorEnvelope struct {
+	Error string `json:"error"`
+}
+
+// HelloResponse is the body Hello writes for GET / .
+type HelloResponse struct {
+	Message string `json:"message"`
+}
+
+// AddItemResponse is the body AddItem's async pipeline reports once an item has been stored.
+type AddItemResponse struct {
+	Message string `json:"message"`
+}
+
+// JSON writes body as JSON with the given status code.
+func JSON(w http.ResponseWriter, status int, body any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(body)
+}
+
+// Error writes a `{"error":"..."}` envelope with the given status code.
+func Error(w http.ResponseWriter, status int, err error) {
+	JSON(w, status, errorEnvelope{Error: err.Error()})
+}
+
+// NotFound writes a 404 error envelope.
+func NotFound(w http.ResponseWriter, err error) {
+	Error(w, http.StatusNotFound, err)
+}
+
+// Created writes a 202 Accepted response with a Location header pointing at where the
+// resulting resource (or its in-progress operation) can be polled, plus a JSON body.
+func Created(w http.ResponseWriter, location string, body any) error {
+	w.Header().Set("Location", location)
+	return JSON(w, http.StatusAccepted, body)
+}
@@ -1,21 +1,51 @@
 package app
 
 import (
+	"context"
 	"crypto/sha256"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
+
+	"mercari-build-training/app/operations"
+	"mercari-build-training/app/response"
+	"mercari-build-training/app/stream"
 )
 
+// maxImageSize is the default upper bound on an uploaded image's size. It can be overridden
+// with the MERCARI_MAX_IMAGE_SIZE environment variable (bytes).
+const maxImageSize = 5 << 20 // 5 MiB
+
+// allowedImageMimes are the sniffed content types storeImage accepts, keyed by the mime
+// http.DetectContentType returns. webp is deliberately absent: the stdlib image package has
+// no webp decoder registered (see decodeImage), so a webp upload would silently get no
+// width/height/blurhash -- the metadata this package exists to populate.
+var allowedImageMimes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+func maxImageSizeBytes() int64 {
+	if v := os.Getenv("MERCARI_MAX_IMAGE_SIZE"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return maxImageSize
+}
+
 type Server struct {
 	// Port is the port number to listen on.
 	Port string
@@ -47,21 +77,24 @@ func (s Server) Run() int {
 	defer db.Close()
 
 	// set up handlers
-	itemRepo,err := NewItemRepository(db)
+	itemRepo, err := NewItemRepository(db)
 	if err != nil {
 		slog.Error("failed to create item repository: ", "error", err)
 		return 1
 	}
-	h := &Handlers{imgDirPath: s.ImageDirPath, itemRepo: itemRepo}
+	h := &Handlers{imgDirPath: s.ImageDirPath, itemRepo: itemRepo, ops: operations.New()}
 
 	// set up routes
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /", h.Hello)
 	mux.HandleFunc("POST /items", h.AddItem)
+	mux.HandleFunc("POST /items/import", h.ImportItems)
 	mux.HandleFunc("GET /items", h.GetItems)
-	mux.HandleFunc("GET /items/{id}", h.GetItemById)
+	mux.HandleFunc("GET /items/{ref...}", h.GetItemById)
 	mux.HandleFunc("GET /images/{filename}", h.GetImage)
 	mux.HandleFunc("GET /search", h.Search)
+	mux.HandleFunc("GET /operations/{id}", h.GetOperation)
+	mux.HandleFunc("GET /operations", h.GetOperations)
 
 	// start the server
 	slog.Info("http server started on", "port", s.Port)
@@ -78,34 +111,31 @@ type Handlers struct {
 	// imgDirPath is the path to the directory storing images.
 	imgDirPath string
 	itemRepo   ItemRepository
-}
-
-type HelloResponse struct {
-	Message string `json:"message"`
+	// ops tracks AddItem's async ingestion jobs so clients can poll their progress.
+	ops *operations.Registry
 }
 
 // Hello is a handler to return a Hello, world! message for GET / .
 func (s *Handlers) Hello(w http.ResponseWriter, r *http.Request) {
-	resp := HelloResponse{Message: "Hello, world!"}
-	err := json.NewEncoder(w).Encode(resp)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	resp := response.HelloResponse{Message: "Hello, world!"}
+	if err := response.JSON(w, http.StatusOK, resp); err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 }
 
 type AddItemRequest struct {
-	Name string `form:"name"`
+	Name     string `form:"name"`
 	Category string `form:"category"` // STEP 4-2: add a category field
-	Image []byte `form:"image"` // STEP 4-4: add an image field
-}
-
-type AddItemResponse struct {
-	Message string `json:"message"`
+	// ImagePath is a temp file holding the uploaded image, already hashed and size-capped by
+	// streamImageToTemp. storeImage consumes and removes it.
+	ImagePath string
+	ImageHash string
 }
 
-// parseAddItemRequest parses and validates the request to add an item.
-func parseAddItemRequest(r *http.Request) (*AddItemRequest, error) {
+// parseAddItemRequest parses and validates the request to add an item. If progress is
+// non-nil, it's called as the image upload is streamed to disk (see streamImageToTemp).
+func (s *Handlers) parseAddItemRequest(r *http.Request, progress func(copied, total int64)) (*AddItemRequest, error) {
 	var req = &AddItemRequest{}
 
 	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
@@ -113,7 +143,7 @@ func parseAddItemRequest(r *http.Request) (*AddItemRequest, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse multipart form: %w", err)
 		}
-	
+
 		req.Name = r.FormValue("name")
 		req.Category = r.FormValue("category")
 
@@ -125,21 +155,18 @@ func parseAddItemRequest(r *http.Request) (*AddItemRequest, error) {
 		} else {
 			defer file.Close()
 
-			if !strings.HasSuffix(strings.ToLower(header.Filename), ".jpg") && !strings.HasSuffix(strings.ToLower(header.Filename), ".jpeg") {
-				return nil, errors.New("only .jpg or .jpeg files are allowed")
-			}
-
-			imageData, err := io.ReadAll(file)
+			path, hash, err := s.streamImageToTemp(file, maxImageSizeBytes(), func(copied int64) {
+				if progress != nil {
+					progress(copied, header.Size)
+				}
+			})
 			if err != nil {
-				return nil, fmt.Errorf("failed to read image data: %w", err)
-			}
-			if len(imageData) == 0 {
-				return nil, errors.New("image data is empty")
+				return nil, err
 			}
-
-			req.Image = imageData
+			req.ImagePath = path
+			req.ImageHash = hash
 		}
-	
+
 	} else {
 		err := r.ParseForm()
 		if err != nil {
@@ -157,24 +184,57 @@ func parseAddItemRequest(r *http.Request) (*AddItemRequest, error) {
 	if req.Category == "" {
 		return nil, errors.New("category is required")
 	}
+	if req.ImagePath == "" {
+		return nil, errors.New("image is required")
+	}
 
 	return req, nil
 }
 
+// progressStatus is one line of an ndjson progress stream: `status` is always set, the rest
+// are filled in as they become relevant to that stage.
+type progressStatus struct {
+	Status   string `json:"status"`
+	Progress int    `json:"progress,omitempty"`
+	Total    int    `json:"total,omitempty"`
+	Image    string `json:"image,omitempty"`
+	ID       int    `json:"id,omitempty"`
+}
+
 // AddItem is a handler to add a new item for POST /items .
+// When called with `Accept: application/x-ndjson`, it streams its progress as a series of
+// flushed JSON lines on the request goroutine. Otherwise the image hashing/storage/insert
+// pipeline runs in the background and AddItem immediately returns 202 Accepted with a
+// Location header pointing at an operation the client can poll for the result.
 func (s *Handlers) AddItem(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	if strings.Contains(r.Header.Get("Accept"), "application/x-ndjson") {
+		s.addItemStreaming(w, r)
+		return
+	}
 
-	req, err := parseAddItemRequest(r)
+	req, err := s.parseAddItemRequest(r, nil)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	fileName, err := s.storeImage(req.Image)
+	op := s.ops.Start()
+	go s.runAddItem(context.WithoutCancel(r.Context()), op.ID, req)
+
+	if err := response.Created(w, "/operations/"+op.ID, op); err != nil {
+		slog.Error("failed to write add item response: ", "error", err)
+	}
+}
+
+// runAddItem performs the storeImage -> Insert pipeline for AddItem's async path, reporting
+// its outcome through s.ops instead of an HTTP response.
+func (s *Handlers) runAddItem(ctx context.Context, opID string, req *AddItemRequest) {
+	s.ops.SetRunning(opID)
+
+	stored, err := s.storeImage(req.ImagePath, req.ImageHash)
 	if err != nil {
 		slog.Error("failed to store image: ", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		s.ops.SetError(opID, err)
 		return
 	}
 
@@ -183,53 +243,226 @@ func (s *Handlers) AddItem(w http.ResponseWriter, r *http.Request) {
 		// STEP 4-2: add a category field
 		Category: req.Category,
 		// STEP 4-4: add an image field
-		ImageName: fileName,
+		ImageName: stored.fileName,
+		ImageHash: stored.hash,
+		Mime:      stored.mime,
+		Width:     stored.width,
+		Height:    stored.height,
+		Blurhash:  stored.blurhash,
 	}
 	message := fmt.Sprintf("item received: %s (category: %s)", item.Name, item.Category)
 	slog.Info(message)
 
 	// STEP 4-2: add an implementation to store an item
-	err = s.itemRepo.Insert(ctx, item)
-	if err != nil {
+	if err := s.itemRepo.Insert(ctx, item); err != nil {
 		slog.Error("failed to store item: ", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		s.ops.SetError(opID, err)
 		return
 	}
 
-	resp := AddItemResponse{Message: message}
-	err = json.NewEncoder(w).Encode(resp)
+	s.ops.SetDone(opID, response.AddItemResponse{Message: message})
+}
+
+// addItemStreaming is AddItem's `Accept: application/x-ndjson` path: it runs the same
+// pipeline synchronously on the request goroutine, emitting a progress line per stage.
+func (s *Handlers) addItemStreaming(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sw, ok := stream.New(w)
+	if !ok {
+		response.Error(w, http.StatusInternalServerError, errors.New("streaming not supported"))
+		return
+	}
+
+	sw.WriteJSON(progressStatus{Status: "parsing"})
+
+	req, err := s.parseAddItemRequest(r, func(copied, total int64) {
+		sw.WriteJSON(progressStatus{Status: "hashing", Progress: int(copied), Total: int(total)})
+	})
+	if err != nil {
+		sw.WriteError(err)
+		return
+	}
+
+	stored, err := s.storeImage(req.ImagePath, req.ImageHash)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		slog.Error("failed to store image: ", "error", err)
+		sw.WriteError(err)
 		return
 	}
+
+	sw.WriteJSON(progressStatus{Status: "stored", Image: stored.fileName})
+
+	item := &Item{
+		Name: req.Name,
+		// STEP 4-2: add a category field
+		Category: req.Category,
+		// STEP 4-4: add an image field
+		ImageName: stored.fileName,
+		ImageHash: stored.hash,
+		Mime:      stored.mime,
+		Width:     stored.width,
+		Height:    stored.height,
+		Blurhash:  stored.blurhash,
+	}
+	slog.Info(fmt.Sprintf("item received: %s (category: %s)", item.Name, item.Category))
+
+	// STEP 4-2: add an implementation to store an item
+	if err := s.itemRepo.Insert(ctx, item); err != nil {
+		slog.Error("failed to store item: ", "error", err)
+		sw.WriteError(err)
+		return
+	}
+
+	sw.WriteJSON(progressStatus{Status: "indexed", ID: item.ID})
 }
 
-// storeImage stores an image and returns the file path and an error if any.
-// this method calculates the hash sum of the image as a file name to avoid the duplication of a same file
-// and stores it in the image directory.
-func (s *Handlers) storeImage(image []byte) (filePath string, err error) {
-	// STEP 4-4: add an implementation to store an image
-	// TODO:
-	// - calc hash sum
-	// - build image file path
-	// - check if the image already exists
-	// - store image
-	// - return the image file path
+// GetOperation handles GET /operations/{id}, returning the current state of an AddItem job.
+func (s *Handlers) GetOperation(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	op, ok := s.ops.Get(id)
+	if !ok {
+		response.NotFound(w, fmt.Errorf("operation not found: %s", id))
+		return
+	}
 
-	hash := sha256.Sum256(image)
-	fileName := fmt.Sprintf("%x.jpg", hash)
-	filePath = filepath.Join(s.imgDirPath, fileName)
+	response.JSON(w, http.StatusOK, op)
+}
+
+// GetOperations handles GET /operations, listing every tracked AddItem job.
+func (s *Handlers) GetOperations(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, struct {
+		Operations []operations.Operation `json:"operations"`
+	}{Operations: s.ops.List()})
+}
 
-	if _, err := os.Stat(filePath); err == nil {
-		return filePath, nil
+// storedImage carries the metadata the ingestion pipeline derives from an uploaded image,
+// mirroring the columns storeImage persists onto Item.
+type storedImage struct {
+	fileName string
+	hash     string
+	mime     string
+	width    int
+	height   int
+	blurhash string
+}
+
+// streamImageToTemp copies an uploaded image into a temp file inside s.imgDirPath (so the
+// later rename in storeImage is on the same filesystem) while hashing it with sha256, without
+// ever buffering the whole image in memory. It aborts once more than limit bytes have been
+// read. If progress is non-nil, it's called with the running byte count after each chunk.
+// storeImage removes the returned file when it consumes it; a path that is never passed to
+// storeImage stays on disk.
+func (s *Handlers) streamImageToTemp(r io.Reader, limit int64, progress func(copied int64)) (path string, hash string, err error) {
+	f, err := os.CreateTemp(s.imgDirPath, "upload-*.tmp")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	dst := io.MultiWriter(f, hasher)
+
+	var copied int64
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			copied += int64(n)
+			if copied > limit {
+				os.Remove(f.Name())
+				return "", "", fmt.Errorf("image exceeds the maximum allowed size of %d bytes", limit)
+			}
+			if _, err := dst.Write(buf[:n]); err != nil {
+				os.Remove(f.Name())
+				return "", "", fmt.Errorf("failed to write temp file: %w", err)
+			}
+			if progress != nil {
+				progress(copied)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			os.Remove(f.Name())
+			return "", "", fmt.Errorf("failed to read image: %w", readErr)
+		}
+	}
+
+	if copied == 0 {
+		os.Remove(f.Name())
+		return "", "", errors.New("image is empty")
 	}
 
-	err = os.WriteFile(filePath, image, 0644)
+	return f.Name(), fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
+
+// storeImage sniffs, validates and persists an image already streamed to tempPath (see
+// streamImageToTemp), returning the metadata needed to populate an Item. The file is named
+// after its sha256 hash so re-uploading the same image is a no-op; tempPath is always removed
+// or renamed away before storeImage returns.
+func (s *Handlers) storeImage(tempPath, hash string) (*storedImage, error) {
+	f, err := os.Open(tempPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to write image file: %w", err)
+		return nil, fmt.Errorf("failed to open temp file: %w", err)
+	}
+	defer f.Close()
+	defer os.Remove(tempPath)
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(f, sniff)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read image: %w", err)
 	}
+	mime := http.DetectContentType(sniff[:n])
 
-	return filePath, nil
+	ext, ok := allowedImageMimes[mime]
+	if !ok {
+		return nil, fmt.Errorf("unsupported image type: %s", mime)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek image: %w", err)
+	}
+	img, width, height := decodeImage(f)
+
+	fileName := hash + ext
+	filePath := filepath.Join(s.imgDirPath, fileName)
+
+	if _, err := os.Stat(filePath); err != nil {
+		if err := os.Rename(tempPath, filePath); err != nil {
+			return nil, fmt.Errorf("failed to store image file: %w", err)
+		}
+	}
+
+	var blurhash string
+	if img != nil {
+		blurhash = encodeBlurhash(img, blurhashComponentsX, blurhashComponentsY)
+	}
+
+	return &storedImage{
+		fileName: fileName,
+		hash:     hash,
+		mime:     mime,
+		width:    width,
+		height:   height,
+		blurhash: blurhash,
+	}, nil
+}
+
+// decodeImage decodes an uploaded image's pixels and dimensions using the stdlib image
+// package. Only jpeg and png decoders are registered (see the blank imports above), which is
+// why storeImage rejects webp before calling it. For any input it can't decode it returns a nil
+// img and zero dimensions, leaving width, height and blurhash unset on the stored Item.
+func decodeImage(r io.Reader) (img image.Image, width, height int) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, 0, 0
+	}
+	bounds := img.Bounds()
+	return img, bounds.Dx(), bounds.Dy()
 }
 
 type GetImageRequest struct {
@@ -256,7 +489,7 @@ func (s *Handlers) GetImage(w http.ResponseWriter, r *http.Request) {
 	req, err := parseGetImageRequest(r)
 	if err != nil {
 		slog.Warn("failed to parse get image request: ", "error", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
@@ -264,7 +497,7 @@ func (s *Handlers) GetImage(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if !errors.Is(err, errImageNotFound) {
 			slog.Warn("failed to build image path: ", "error", err)
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, err)
 			return
 		}
 
@@ -288,8 +521,12 @@ func (s *Handlers) buildImagePath(imageFileName string) (string, error) {
 	}
 
 	// validate the image suffix
-	if !strings.HasSuffix(imgPath, ".jpg") && !strings.HasSuffix(imgPath, ".jpeg") {
-		return "", fmt.Errorf("image path does not end with .jpg or .jpeg: %s", imgPath)
+	validSuffix := strings.HasSuffix(imgPath, ".jpeg")
+	for _, ext := range allowedImageMimes {
+		validSuffix = validSuffix || strings.HasSuffix(imgPath, ext)
+	}
+	if !validSuffix {
+		return "", fmt.Errorf("image path has an unsupported suffix: %s", imgPath)
 	}
 
 	// check if the image exists
@@ -301,88 +538,112 @@ func (s *Handlers) buildImagePath(imageFileName string) (string, error) {
 	return imgPath, nil
 }
 
+// listItem is the wire shape of an item in GetItems' envelope; it surfaces the DB id that
+// Item normally hides (json:"-") so the frontend can page with since_id/next_offset.
+type listItem struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Category  string `json:"category"`
+	Image     string `json:"image_name"`
+	ImageHash string `json:"image_hash"`
+	Mime      string `json:"mime"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Blurhash  string `json:"blurhash"`
+}
+
+// parseListItemsQuery decodes the filter/pagination query params for GET /items. A request
+// with none of these params set produces the zero value, which List treats the same as the
+// old unconditional GetAll.
+func parseListItemsQuery(r *http.Request) ListItemsQuery {
+	q := r.URL.Query()
+
+	query := ListItemsQuery{
+		Category: q.Get("category"),
+		Name:     q.Get("name"),
+		Hash:     q.Get("hash"),
+		Order:    q.Get("order"),
+	}
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 {
+		query.Limit = v
+	}
+	if v, err := strconv.Atoi(q.Get("offset")); err == nil && v > 0 {
+		query.Offset = v
+	}
+	if v, err := strconv.Atoi(q.Get("since_id")); err == nil && v > 0 {
+		query.SinceID = v
+	}
+
+	return query
+}
 
+// GetItems handles GET /items, returning a paginated, optionally filtered envelope of items.
 func (s *Handlers) GetItems(w http.ResponseWriter, r *http.Request) {
-	items, err := s.itemRepo.GetAll(r.Context())
+	query := parseListItemsQuery(r)
+
+	items, total, err := s.itemRepo.List(r.Context(), query)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	response := struct {
-		Items []struct {
-			ID       int    `json:"id"`
-			Name     string `json:"name"`
-			Category string `json:"category"`
-			Image    string `json:"image_name"`
-		} `json:"items"`
-	}{}
+	envelope := struct {
+		Items      []listItem `json:"items"`
+		Total      int        `json:"total"`
+		NextOffset *int       `json:"next_offset"`
+	}{
+		Items: []listItem{},
+		Total: total,
+	}
 
 	for _, item := range items {
-		response.Items = append(response.Items, struct {
-			ID       int    `json:"id"`
-			Name     string `json:"name"`
-			Category string `json:"category"`
-			Image    string `json:"image_name"`
-		}{
-			ID:       item.ID,
-			Name:     item.Name,
-			Category: item.Category,
-			Image:    item.ImageName,
+		envelope.Items = append(envelope.Items, listItem{
+			ID:        item.ID,
+			Name:      item.Name,
+			Category:  item.Category,
+			Image:     item.ImageName,
+			ImageHash: item.ImageHash,
+			Mime:      item.Mime,
+			Width:     item.Width,
+			Height:    item.Height,
+			Blurhash:  item.Blurhash,
 		})
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-}
-
-
-type GetItemByIdRequest struct {
-	Id string
-}
-
-func parseGetItemByIdRequest(r *http.Request) (*GetItemByIdRequest, error) {
-	req := &GetItemByIdRequest{
-		Id: r.PathValue("item_id"),
+	if query.Limit > 0 {
+		if next := query.Offset + len(items); next < total {
+			envelope.NextOffset = &next
+		}
 	}
 
-	if req.Id == "" {
-		return nil, errors.New("id is required")
+	if err := response.JSON(w, http.StatusOK, envelope); err != nil {
+		slog.Error("failed to write get items response: ", "error", err)
 	}
-
-	return req, nil
 }
 
+// GetItemById handles GET /items/{ref}, where ref is either a numeric database id or a
+// "category/name[@sha256:<hex>]" reference (see ParseItemReference).
 func (s *Handlers) GetItemById(w http.ResponseWriter, r *http.Request) {
-	req, err := parseGetItemByIdRequest(r)
+	ref, err := ParseItemReference(r.PathValue("ref"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, err)
+		return
 	}
 
-	item, err := s.itemRepo.GetByID(r.Context(), req.Id)
+	item, err := s.itemRepo.GetByRef(r.Context(), ref)
 	if err != nil {
 		if errors.Is(err, errItemNotFound) {
 			slog.Warn("item not exist: ", "error", err)
-			http.Error(w, err.Error(), http.StatusNotFound)
+			response.NotFound(w, err)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
-	}
-
-	jsonData, err := json.Marshal(item)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(jsonData)
+	response.JSON(w, http.StatusOK, item)
 }
 
-
 type GetItemByKeywordRequest struct {
 	Keyword string
 }
@@ -403,30 +664,24 @@ func parseGetItemByKeywordRequest(r *http.Request) (*GetItemByKeywordRequest, er
 func (s *Handlers) Search(w http.ResponseWriter, r *http.Request) {
 	req, err := parseGetItemByKeywordRequest(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
 	items, err := s.itemRepo.Search(r.Context(), req.Keyword)
-
 	if err != nil {
 		if errors.Is(err, errItemNotFound) {
 			slog.Warn("item not exist: ", "error", err)
-			http.Error(w, err.Error(), http.StatusNotFound)
+			response.NotFound(w, err)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, err)
+		return
 	}
 
 	if items == nil {
 		items = []Item{}
 	}
 
-	jsonData, err := json.Marshal(items)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(jsonData)
-}
\ No newline at end of file
+	response.JSON(w, http.StatusOK, items)
+}
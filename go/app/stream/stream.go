@@ -0,0 +1,50 @@
+// Package stream provides a small helper for handlers that want to report progress as a
+// series of flushed JSON lines (application/x-ndjson) instead of a single JSON response.
+package stream
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Writer wraps an http.ResponseWriter and flushes after every line so a client reading the
+// response body sees progress as it happens instead of only once the handler returns.
+type Writer struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// New sets the ndjson content type and wraps w. It returns false if w doesn't support
+// flushing, in which case the caller should fall back to a regular JSON response.
+func New(w http.ResponseWriter) (*Writer, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	return &Writer{w: w, flusher: flusher}, true
+}
+
+// WriteJSON marshals v, writes it as a single line, and flushes it to the client.
+func (s *Writer) WriteJSON(v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	s.flusher.Flush()
+	return nil
+}
+
+// WriteError writes a `{"error":"..."}` line and flushes it, for reporting a failure
+// mid-stream once the response has already started (so http.Error can no longer be used).
+func (s *Writer) WriteError(err error) error {
+	return s.WriteJSON(struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+}